@@ -0,0 +1,190 @@
+// Package certify provides tools for keeping your TLS certificates short
+// lived, and thus more secure.
+package certify
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// certifyArc is an arbitrarily-chosen integer under the IANA-documented
+// 2.25 ("UUID") arc (see ITU-T X.667 / RFC 4122 annex), used below to mint
+// OIDs for this package's own CSR extensions without registering a private
+// enterprise number (PEN) we don't hold. Squatting on an unregistered
+// 1.3.6.1.4.1.<n> PEN arc risks silently colliding with whichever
+// organization that number is actually assigned to; the 2.25 arc exists
+// precisely so an arbitrary value can be used instead, without
+// registration, as long as it's chosen so collisions are implausible.
+// Go's encoding/asn1 caps a parsed OID arc component at math.MaxInt32
+// (so a value round-trips through x509.ParseCertificateRequest on any
+// platform), which rules out a full 128-bit UUID; this is instead a
+// large random value within that bound — still implausible to collide
+// with anyone else's independently-chosen value.
+const certifyArc = 1847293058
+
+// OIDRequestedTTL is used to carry a CertConfig's RequestedTTL as a CSR
+// extension, ASN.1-encoded as an INTEGER number of seconds. A SignPolicy
+// such as policy.MaxTTL can inspect it to enforce a maximum lifetime
+// across issuers.
+var OIDRequestedTTL = asn1.ObjectIdentifier{2, 25, certifyArc, 1}
+
+// OIDRequestedKeyUsage is used to carry a CertConfig's requested key usage
+// as a CSR extension, ASN.1-encoded as a SEQUENCE of an INTEGER
+// x509.KeyUsage bitmask followed by a SEQUENCE OF extended key usage
+// OIDs. internal/csr.FromCertConfig sets it whenever CertConfig.SpiffeID
+// is set, requesting the digital signature and key encipherment usages,
+// and server and client auth extended usages, that a SPIFFE X.509-SVID
+// needs. A SignPolicy, or an issuer's backend configured to honour
+// CSR-requested key usage, can inspect it to actually grant those
+// usages, rather than relying on out-of-band backend configuration
+// alone.
+var OIDRequestedKeyUsage = asn1.ObjectIdentifier{2, 25, certifyArc, 2}
+
+// KeyGenerator is used to generate a private key for a certificate signing
+// request.
+type KeyGenerator interface {
+	// Generate should return a new private key on every call.
+	Generate() (crypto.PrivateKey, error)
+}
+
+// CertConfig configures the properties of a certificate request.
+type CertConfig struct {
+	// SubjectAlternativeNames is a list of SANs to include in the
+	// requested certificate. The common name passed to Issue is not
+	// automatically included in this list.
+	SubjectAlternativeNames []string
+	// IPSubjectAlternativeNames is a list of IP address SANs to include
+	// in the requested certificate.
+	IPSubjectAlternativeNames []net.IP
+	// KeyGenerator is used to generate the private key backing the
+	// requested certificate. If nil, a default generator is used.
+	KeyGenerator KeyGenerator
+	// SpiffeID, if set, is carried as a "spiffe://" URI SAN on the
+	// requested certificate, and marks it as a SPIFFE X.509-SVID. The
+	// CSR also carries the OIDRequestedKeyUsage extension, requesting
+	// both digital signature and key encipherment key usages, and both
+	// server and client auth extended key usages, so they're usable by
+	// consumers such as Istio or SPIRE. The issuer's backend still has
+	// to be configured to honour (or itself set) those usages; the
+	// extension only records that they were requested.
+	SpiffeID *url.URL
+	// RequestedTTL, if non-zero, is carried on the CSR as the
+	// OIDRequestedTTL extension, so that a SignPolicy such as
+	// policy.MaxTTL can enforce a cap on it. It doesn't by itself
+	// instruct an issuer's backend to honour the requested lifetime;
+	// that's still down to the backend's own configuration (e.g. a
+	// Vault role's TTL, or an Issuer's own TimeToLive field).
+	RequestedTTL time.Duration
+	// SignPolicy, if set, overrides any SignPolicy configured on the
+	// Issuer or Certify for this request.
+	SignPolicy SignPolicy
+}
+
+// SignPolicy is invoked with the certificate signing request assembled
+// from a CertConfig, before it is sent to an Issuer's backend. It may
+// reject the request by returning an error, or rewrite csr in place, e.g.
+// to enforce allowed DNS suffixes, forbid IP SANs, or require specific
+// SANs. This lets operators safely share one Issuer across many
+// workloads.
+type SignPolicy interface {
+	Approve(csr *x509.CertificateRequest) error
+}
+
+// Issuer is something that issues certificates.
+type Issuer interface {
+	// Issue takes a context, a common name and a certificate
+	// configuration, and returns a signed certificate for that common
+	// name and configuration.
+	Issue(ctx context.Context, commonName string, conf *CertConfig) (*tls.Certificate, error)
+}
+
+// RevocationReason mirrors the CRL reason codes defined in RFC 5280
+// section 5.3.1, for use with Revoker.Revoke.
+type RevocationReason int
+
+// Revocation reasons, as defined in RFC 5280 section 5.3.1. Reason code 7
+// is intentionally unused, as per the RFC.
+const (
+	RevocationReasonUnspecified          RevocationReason = 0
+	RevocationReasonKeyCompromise        RevocationReason = 1
+	RevocationReasonCACompromise         RevocationReason = 2
+	RevocationReasonAffiliationChanged   RevocationReason = 3
+	RevocationReasonSuperseded           RevocationReason = 4
+	RevocationReasonCessationOfOperation RevocationReason = 5
+	RevocationReasonCertificateHold      RevocationReason = 6
+	RevocationReasonRemoveFromCRL        RevocationReason = 8
+	RevocationReasonPrivilegeWithdrawn   RevocationReason = 9
+	RevocationReasonAACompromise         RevocationReason = 10
+)
+
+// Revoker is implemented by Issuers that can revoke a certificate they
+// previously issued.
+type Revoker interface {
+	// Revoke revokes cert with the issuing backend, recording reason as
+	// the cause.
+	Revoke(ctx context.Context, cert *x509.Certificate, reason RevocationReason) error
+}
+
+// Cache is used to store and retrieve issued certificates between issuance
+// and renewal.
+type Cache interface {
+	// Get returns the certificate cached for key. On a cache miss, it
+	// must return (nil, nil), not an error — callers such as
+	// Certify.Delete rely on a miss being distinguishable from a
+	// lookup failure.
+	Get(ctx context.Context, key string) (*tls.Certificate, error)
+	Put(ctx context.Context, key string, cert *tls.Certificate) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Certify ties an Issuer and a Cache together, to transparently issue,
+// cache and renew certificates.
+type Certify struct {
+	// CommonName is the Common Name to request for certificates that
+	// don't otherwise have one available (e.g. via SNI).
+	CommonName string
+	// Issuer is used to issue new certificates.
+	Issuer Issuer
+	// Cache is used to store issued certificates between issuance and
+	// renewal.
+	Cache Cache
+	// CertConfig is used to configure any additional properties of
+	// issued certificates, including its own SignPolicy.
+	CertConfig *CertConfig
+
+	mu sync.Mutex
+}
+
+// Delete removes the cached certificate for key. If the configured Issuer
+// implements Revoker, the certificate is revoked first, with
+// RevocationReasonSuperseded.
+//
+// Certify doesn't yet implement automatic certificate rotation/renewal,
+// so Delete is currently the only path that triggers revocation; there's
+// no hook yet for revoking a certificate that's replaced by a
+// renewal-driven eviction.
+func (c *Certify) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if revoker, ok := c.Issuer.(Revoker); ok {
+		cert, err := c.Cache.Get(ctx, key)
+		switch {
+		case err != nil:
+			return err
+		case cert != nil && cert.Leaf != nil:
+			if err := revoker.Revoke(ctx, cert.Leaf, RevocationReasonSuperseded); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.Cache.Delete(ctx, key)
+}
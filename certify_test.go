@@ -0,0 +1,128 @@
+package certify
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"testing"
+)
+
+type fakeCache struct {
+	certs     map[string]*tls.Certificate
+	deleted   []string
+	getErr    error
+	deleteErr error
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{certs: map[string]*tls.Certificate{}}
+}
+
+func (f *fakeCache) Get(ctx context.Context, key string) (*tls.Certificate, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	return f.certs[key], nil // (nil, nil) on a miss, per the Cache contract
+}
+
+func (f *fakeCache) Put(ctx context.Context, key string, cert *tls.Certificate) error {
+	f.certs[key] = cert
+	return nil
+}
+
+func (f *fakeCache) Delete(ctx context.Context, key string) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	f.deleted = append(f.deleted, key)
+	delete(f.certs, key)
+	return nil
+}
+
+type fakeRevokingIssuer struct {
+	revoked   []*x509.Certificate
+	revokeErr error
+}
+
+func (f *fakeRevokingIssuer) Issue(ctx context.Context, commonName string, conf *CertConfig) (*tls.Certificate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeRevokingIssuer) Revoke(ctx context.Context, cert *x509.Certificate, reason RevocationReason) error {
+	if f.revokeErr != nil {
+		return f.revokeErr
+	}
+	f.revoked = append(f.revoked, cert)
+	return nil
+}
+
+type fakeIssuer struct{}
+
+func (fakeIssuer) Issue(ctx context.Context, commonName string, conf *CertConfig) (*tls.Certificate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestCertifyDeleteRevokesOnCacheHit(t *testing.T) {
+	cache := newFakeCache()
+	leaf := &x509.Certificate{}
+	cache.certs["key"] = &tls.Certificate{Leaf: leaf}
+	issuer := &fakeRevokingIssuer{}
+
+	c := &Certify{Issuer: issuer, Cache: cache}
+	if err := c.Delete(context.Background(), "key"); err != nil {
+		t.Fatalf("Delete() returned error: %v", err)
+	}
+
+	if len(issuer.revoked) != 1 || issuer.revoked[0] != leaf {
+		t.Errorf("expected the cached leaf to be revoked, got %v", issuer.revoked)
+	}
+	if len(cache.deleted) != 1 || cache.deleted[0] != "key" {
+		t.Errorf("expected the cache entry to be deleted, got %v", cache.deleted)
+	}
+}
+
+func TestCertifyDeleteSkipsRevokeOnCacheMiss(t *testing.T) {
+	cache := newFakeCache() // nothing cached under "key"
+	issuer := &fakeRevokingIssuer{}
+
+	c := &Certify{Issuer: issuer, Cache: cache}
+	if err := c.Delete(context.Background(), "key"); err != nil {
+		t.Fatalf("Delete() returned error: %v", err)
+	}
+
+	if len(issuer.revoked) != 0 {
+		t.Errorf("expected no revocation on a cache miss, got %v", issuer.revoked)
+	}
+	if len(cache.deleted) != 1 {
+		t.Errorf("expected Cache.Delete to still be called on a miss, got %v", cache.deleted)
+	}
+}
+
+func TestCertifyDeleteSkipsRevokeWhenIssuerIsNotARevoker(t *testing.T) {
+	cache := newFakeCache()
+	cache.certs["key"] = &tls.Certificate{Leaf: &x509.Certificate{}}
+
+	c := &Certify{Issuer: fakeIssuer{}, Cache: cache}
+	if err := c.Delete(context.Background(), "key"); err != nil {
+		t.Fatalf("Delete() returned error: %v", err)
+	}
+
+	if len(cache.deleted) != 1 {
+		t.Errorf("expected Cache.Delete to be called, got %v", cache.deleted)
+	}
+}
+
+func TestCertifyDeletePropagatesRevokeError(t *testing.T) {
+	cache := newFakeCache()
+	cache.certs["key"] = &tls.Certificate{Leaf: &x509.Certificate{}}
+	issuer := &fakeRevokingIssuer{revokeErr: errors.New("revoke failed")}
+
+	c := &Certify{Issuer: issuer, Cache: cache}
+	if err := c.Delete(context.Background(), "key"); err == nil {
+		t.Fatal("expected Delete() to propagate the Revoke error")
+	}
+	if len(cache.deleted) != 0 {
+		t.Errorf("expected Cache.Delete not to run after a failed revoke, got %v", cache.deleted)
+	}
+}
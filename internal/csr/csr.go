@@ -0,0 +1,110 @@
+// Package csr builds certificate signing requests from a certify.CertConfig,
+// for use by the concrete Issuer implementations.
+package csr
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/utilitywarehouse/certify"
+)
+
+// oidExtKeyUsageServerAuth and oidExtKeyUsageClientAuth are the well-known
+// PKIX extended key usage OIDs for TLS server and client authentication,
+// as defined in RFC 5280 section 4.2.1.12.
+var (
+	oidExtKeyUsageServerAuth = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 1}
+	oidExtKeyUsageClientAuth = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 2}
+)
+
+// requestedKeyUsage is the ASN.1 structure ASN.1-marshalled into the
+// certify.OIDRequestedKeyUsage extension.
+type requestedKeyUsage struct {
+	KeyUsage     int
+	ExtKeyUsages []asn1.ObjectIdentifier
+}
+
+type defaultKeyGenerator struct{}
+
+func (defaultKeyGenerator) Generate() (crypto.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, 2048)
+}
+
+// FromCertConfig generates a new private key and a PEM-encoded certificate
+// signing request for commonName, using the SANs and key generator
+// configured on conf. It returns the PEM-encoded CSR and private key.
+func FromCertConfig(commonName string, conf *certify.CertConfig) (csrPEM []byte, keyPEM []byte, err error) {
+	keyGen := conf.KeyGenerator
+	if keyGen == nil {
+		keyGen = defaultKeyGenerator{}
+	}
+
+	priv, err := keyGen.Generate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.New("csr: generated private key does not implement crypto.Signer")
+	}
+
+	template := &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    conf.SubjectAlternativeNames,
+		IPAddresses: conf.IPSubjectAlternativeNames,
+	}
+	if conf.SpiffeID != nil {
+		template.URIs = []*url.URL{conf.SpiffeID}
+
+		kuValue, err := asn1.Marshal(requestedKeyUsage{
+			KeyUsage:     int(x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment),
+			ExtKeyUsages: []asn1.ObjectIdentifier{oidExtKeyUsageServerAuth, oidExtKeyUsageClientAuth},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+			Id:    certify.OIDRequestedKeyUsage,
+			Value: kuValue,
+		})
+	}
+	if conf.RequestedTTL != 0 {
+		ttlValue, err := asn1.Marshal(int64(conf.RequestedTTL / time.Second))
+		if err != nil {
+			return nil, nil, err
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+			Id:    certify.OIDRequestedTTL,
+			Value: ttlValue,
+		})
+	}
+
+	if conf.SignPolicy != nil {
+		if err := conf.SignPolicy.Approve(template); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	csrPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+
+	return csrPEM, keyPEM, nil
+}
@@ -0,0 +1,123 @@
+package csr
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/utilitywarehouse/certify"
+	"github.com/utilitywarehouse/certify/policy"
+)
+
+func TestFromCertConfigRequestedTTL(t *testing.T) {
+	conf := &certify.CertConfig{RequestedTTL: 2 * time.Hour}
+
+	csrPEM, _, err := FromCertConfig("example.com", conf)
+	if err != nil {
+		t.Fatalf("FromCertConfig() returned error: %v", err)
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	parsed, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("parsing generated CSR: %v", err)
+	}
+
+	var found bool
+	for _, ext := range parsed.Extensions {
+		if !ext.Id.Equal(certify.OIDRequestedTTL) {
+			continue
+		}
+		found = true
+
+		var seconds int64
+		if _, err := asn1.Unmarshal(ext.Value, &seconds); err != nil {
+			t.Fatalf("unmarshalling requested TTL extension: %v", err)
+		}
+		if got := time.Duration(seconds) * time.Second; got != conf.RequestedTTL {
+			t.Errorf("requested TTL extension = %s, want %s", got, conf.RequestedTTL)
+		}
+	}
+	if !found {
+		t.Error("generated CSR did not carry the requested TTL extension")
+	}
+}
+
+func TestFromCertConfigSpiffeIDRequestsKeyUsage(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://example.org/ns/default/sa/my-workload")
+	conf := &certify.CertConfig{SpiffeID: spiffeID}
+
+	csrPEM, _, err := FromCertConfig("example.com", conf)
+	if err != nil {
+		t.Fatalf("FromCertConfig() returned error: %v", err)
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	parsed, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("parsing generated CSR: %v", err)
+	}
+
+	var found bool
+	for _, ext := range parsed.Extensions {
+		if !ext.Id.Equal(certify.OIDRequestedKeyUsage) {
+			continue
+		}
+		found = true
+
+		var ku requestedKeyUsage
+		if _, err := asn1.Unmarshal(ext.Value, &ku); err != nil {
+			t.Fatalf("unmarshalling requested key usage extension: %v", err)
+		}
+		if want := int(x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment); ku.KeyUsage != want {
+			t.Errorf("requested KeyUsage = %d, want %d", ku.KeyUsage, want)
+		}
+		if len(ku.ExtKeyUsages) != 2 {
+			t.Errorf("requested ExtKeyUsages = %v, want server auth and client auth", ku.ExtKeyUsages)
+		}
+	}
+	if !found {
+		t.Error("generated CSR did not carry the requested key usage extension")
+	}
+}
+
+func TestFromCertConfigNoSpiffeIDNoRequestedKeyUsage(t *testing.T) {
+	csrPEM, _, err := FromCertConfig("example.com", &certify.CertConfig{})
+	if err != nil {
+		t.Fatalf("FromCertConfig() returned error: %v", err)
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	parsed, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("parsing generated CSR: %v", err)
+	}
+
+	for _, ext := range parsed.Extensions {
+		if ext.Id.Equal(certify.OIDRequestedKeyUsage) {
+			t.Error("CSR with no SpiffeID should not carry the requested key usage extension")
+		}
+	}
+}
+
+// TestFromCertConfigMaxTTLPolicy exercises policy.MaxTTL end-to-end
+// against a real CertConfig, demonstrating that a RequestedTTL exceeding
+// the policy's maximum is rejected before the CSR is signed.
+func TestFromCertConfigMaxTTLPolicy(t *testing.T) {
+	conf := &certify.CertConfig{
+		RequestedTTL: 2 * time.Hour,
+		SignPolicy:   policy.MaxTTL{Max: time.Hour},
+	}
+
+	if _, _, err := FromCertConfig("example.com", conf); err == nil {
+		t.Error("expected policy.MaxTTL to reject a CSR requesting a TTL over its maximum")
+	}
+
+	conf.RequestedTTL = 30 * time.Minute
+	if _, _, err := FromCertConfig("example.com", conf); err != nil {
+		t.Errorf("expected policy.MaxTTL to approve a CSR within its maximum, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package acme implements the certify.Issuer interface for any
+// certificate authority speaking the ACME protocol (RFC 8555), such as
+// Let's Encrypt, ZeroSSL, step-ca or Boulder.
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+
+	"github.com/utilitywarehouse/certify"
+	"github.com/utilitywarehouse/certify/internal/csr"
+)
+
+// Solver satisfies the authorizations an ACME server requires before it
+// will issue a certificate, by handling one specific challenge type, such
+// as "http-01", "dns-01" or "tls-alpn-01".
+type Solver interface {
+	// ChallengeType returns the ACME challenge type (as used in the
+	// ACME protocol's "type" field, e.g. "http-01") that this Solver is
+	// able to satisfy.
+	ChallengeType() string
+	// Present makes the response to chal available for domain, e.g. by
+	// serving the expected token over HTTP or publishing a DNS record.
+	// chal.Type is always the type returned by ChallengeType.
+	Present(ctx context.Context, domain string, chal *acme.Challenge) error
+	// CleanUp removes anything Present set up for chal.
+	CleanUp(ctx context.Context, domain string, chal *acme.Challenge) error
+}
+
+// Issuer implements the Issuer interface using an RFC 8555 ACME server.
+//
+// AccountKey, DirectoryURL and Solver are required.
+type Issuer struct {
+	// AccountKey is the account private key used to authenticate with
+	// the ACME server. An account is registered for it automatically on
+	// first use if one doesn't already exist.
+	AccountKey crypto.Signer
+	// DirectoryURL is the ACME server's directory endpoint, e.g.
+	// "https://acme-v02.api.letsencrypt.org/directory".
+	DirectoryURL string
+	// Contacts is a list of contact URIs, e.g. "mailto:ops@example.com",
+	// submitted when registering the account.
+	Contacts []string
+	// Solver is used to satisfy the authorizations required to complete
+	// an order.
+	Solver Solver
+	// TLSConfig allows configuration of the TLS config used when
+	// connecting to the ACME server.
+	TLSConfig *tls.Config
+	// SignPolicy, if set, is consulted before the generated CSR is sent
+	// to the ACME server, unless overridden by conf.SignPolicy.
+	SignPolicy certify.SignPolicy
+
+	cli *acme.Client
+}
+
+func (i *Issuer) client() *acme.Client {
+	if i.cli == nil {
+		cli := &acme.Client{
+			Key:          i.AccountKey,
+			DirectoryURL: i.DirectoryURL,
+		}
+		if i.TLSConfig != nil {
+			cli.HTTPClient = &http.Client{
+				Transport: &http.Transport{TLSClientConfig: i.TLSConfig.Clone()},
+			}
+		}
+		i.cli = cli
+	}
+	return i.cli
+}
+
+// Issue issues a certificate from the configured ACME server, registering
+// the account, completing any required challenges via Solver, and
+// finalizing the order.
+func (i *Issuer) Issue(ctx context.Context, commonName string, conf *certify.CertConfig) (*tls.Certificate, error) {
+	cli := i.client()
+
+	if _, err := cli.Register(ctx, &acme.Account{Contact: i.Contacts}, acme.AcceptTOS); err != nil && err != acme.ErrAccountAlreadyExists {
+		return nil, err
+	}
+
+	domains := append([]string{commonName}, conf.SubjectAlternativeNames...)
+	order, err := cli.AuthorizeOrder(ctx, acme.DomainIDs(domains...))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		if err := i.authorize(ctx, cli, authzURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.SignPolicy == nil && i.SignPolicy != nil {
+		c := *conf
+		c.SignPolicy = i.SignPolicy
+		conf = &c
+	}
+
+	csrPEM, keyPEM, err := csr.FromCertConfig(commonName, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	derChain, _, err := cli.CreateOrderCert(ctx, order.FinalizeURL, block.Bytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var chainPEM []byte
+	for _, der := range derChain {
+		chainPEM = append(chainPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	tlsCert, err := tls.X509KeyPair(chainPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	// This can't error since it's called in tls.X509KeyPair above successfully
+	tlsCert.Leaf, _ = x509.ParseCertificate(tlsCert.Certificate[0])
+	return &tlsCert, nil
+}
+
+// pickChallenge selects the challenge of type wantType from challenges. It
+// returns (nil, nil) if one of the challenges is already valid (from a
+// prior attempt), and an error if none of them are of type wantType.
+func pickChallenge(challenges []*acme.Challenge, wantType string) (*acme.Challenge, error) {
+	var chal *acme.Challenge
+	for _, c := range challenges {
+		if c.Status == acme.StatusValid {
+			return nil, nil
+		}
+		if c.Type == wantType {
+			chal = c
+		}
+	}
+	if chal == nil {
+		return nil, fmt.Errorf("no %s challenge available", wantType)
+	}
+	return chal, nil
+}
+
+func (i *Issuer) authorize(ctx context.Context, cli *acme.Client, authzURL string) error {
+	authz, err := cli.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	chal, err := pickChallenge(authz.Challenges, i.Solver.ChallengeType())
+	if err != nil {
+		return fmt.Errorf("acme: authorization %s: %w", authzURL, err)
+	}
+	if chal == nil {
+		// One of the challenges was already valid from a prior attempt.
+		return nil
+	}
+
+	if err := i.Solver.Present(ctx, authz.Identifier.Value, chal); err != nil {
+		return fmt.Errorf("acme: presenting challenge for %s: %w", authz.Identifier.Value, err)
+	}
+	defer i.Solver.CleanUp(ctx, authz.Identifier.Value, chal)
+
+	if _, err := cli.Accept(ctx, chal); err != nil {
+		return err
+	}
+
+	_, err = cli.WaitAuthorization(ctx, authzURL)
+	return err
+}
+
+// Revoke implements certify.Revoker, signing an RFC 8555 revokeCert
+// request for cert with the account key.
+func (i *Issuer) Revoke(ctx context.Context, cert *x509.Certificate, reason certify.RevocationReason) error {
+	return i.client().RevokeCert(ctx, i.AccountKey, cert.Raw, acme.CRLReasonCode(reason))
+}
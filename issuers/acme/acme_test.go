@@ -0,0 +1,51 @@
+package acme
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/acme"
+)
+
+func TestPickChallenge(t *testing.T) {
+	t.Run("selects the challenge matching the solver's type, regardless of order", func(t *testing.T) {
+		challenges := []*acme.Challenge{
+			{Type: "dns-01", URI: "dns"},
+			{Type: "http-01", URI: "http"},
+			{Type: "tls-alpn-01", URI: "tls-alpn"},
+		}
+
+		chal, err := pickChallenge(challenges, "http-01")
+		if err != nil {
+			t.Fatalf("pickChallenge() returned error: %v", err)
+		}
+		if chal == nil || chal.URI != "http" {
+			t.Errorf("pickChallenge() = %v, want the http-01 challenge", chal)
+		}
+	})
+
+	t.Run("returns an error when no challenge matches the solver's type", func(t *testing.T) {
+		challenges := []*acme.Challenge{
+			{Type: "dns-01"},
+			{Type: "tls-alpn-01"},
+		}
+
+		if _, err := pickChallenge(challenges, "http-01"); err == nil {
+			t.Error("expected an error when no http-01 challenge is offered")
+		}
+	})
+
+	t.Run("short-circuits when a challenge is already valid", func(t *testing.T) {
+		challenges := []*acme.Challenge{
+			{Type: "http-01", Status: acme.StatusValid},
+			{Type: "dns-01"},
+		}
+
+		chal, err := pickChallenge(challenges, "dns-01")
+		if err != nil {
+			t.Fatalf("pickChallenge() returned error: %v", err)
+		}
+		if chal != nil {
+			t.Errorf("pickChallenge() = %v, want nil for an already-valid authorization", chal)
+		}
+	})
+}
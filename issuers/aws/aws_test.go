@@ -12,6 +12,7 @@ import (
 	"encoding/pem"
 	"math/big"
 	"net"
+	"net/url"
 	"time"
 
 	api "github.com/aws/aws-sdk-go-v2/aws"
@@ -145,10 +146,14 @@ var _ = Describe("AWS Issuer", func() {
 			return nil
 		}
 
+		spiffeID, err := url.Parse("spiffe://example.org/ns/default/sa/my-workload")
+		Expect(err).NotTo(HaveOccurred())
+
 		cn := "somename.com"
 		conf := &certify.CertConfig{
 			SubjectAlternativeNames:   []string{"extraname.com", "otherextraname.com"},
 			IPSubjectAlternativeNames: []net.IP{net.IPv4(1, 2, 3, 4), net.IPv6loopback},
+			SpiffeID:                  spiffeID,
 			KeyGenerator: keyGeneratorFunc(func() (crypto.PrivateKey, error) {
 				return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 			}),
@@ -163,6 +168,8 @@ var _ = Describe("AWS Issuer", func() {
 		for i, ip := range tlsCert.Leaf.IPAddresses {
 			Expect(ip.Equal(conf.IPSubjectAlternativeNames[i])).To(BeTrue())
 		}
+		Expect(tlsCert.Leaf.URIs).To(HaveLen(1))
+		Expect(tlsCert.Leaf.URIs[0].String()).To(Equal(spiffeID.String()))
 
 		// Check that chain is included
 		Expect(tlsCert.Certificate).To(HaveLen(2))
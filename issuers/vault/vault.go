@@ -4,9 +4,12 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"io"
+	"math/big"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/vault/api"
@@ -31,6 +34,11 @@ type Issuer struct {
 	// Role is the Vault Role that should be used
 	// when issuing certificates.
 	Role string
+	// IssuerRef selects a specific issuer within a multi-issuer PKI
+	// mount, e.g. for cross-signing or key rollover. When set, requests
+	// are routed to /v1/{mount}/issuer/{issuer_ref}/sign/{role} instead
+	// of /v1/{mount}/sign/{role}.
+	IssuerRef string
 	// TLSConfig allows configuration of the TLS config
 	// used when connecting to the Vault server.
 	TLSConfig *tls.Config
@@ -41,6 +49,9 @@ type Issuer struct {
 	// OtherSubjectAlternativeNames defines custom OID/UTF8-string SANs.
 	// The format is the same as OpenSSL: <oid>;<type>:<value> where the only current valid <type> is UTF8.
 	OtherSubjectAlternativeNames []string
+	// SignPolicy, if set, is consulted before the generated CSR is sent
+	// to Vault, unless overridden by conf.SignPolicy.
+	SignPolicy certify.SignPolicy
 
 	cli *api.Client
 }
@@ -83,6 +94,12 @@ func (v *Issuer) Issue(ctx context.Context, commonName string, conf *certify.Cer
 		}
 	}
 
+	if conf.SignPolicy == nil && v.SignPolicy != nil {
+		c := *conf
+		c.SignPolicy = v.SignPolicy
+		conf = &c
+	}
+
 	csrPEM, keyPEM, err := csr.FromCertConfig(commonName, conf)
 	if err != nil {
 		return nil, err
@@ -96,6 +113,9 @@ func (v *Issuer) Issue(ctx context.Context, commonName string, conf *certify.Cer
 		OtherSans:         v.OtherSubjectAlternativeNames,
 		TimeToLive:        ttl(v.TimeToLive),
 	}
+	if conf.SpiffeID != nil {
+		opts.URISans = []string{conf.SpiffeID.String()}
+	}
 
 	secret, err := v.signCSR(ctx, opts)
 	if err != nil {
@@ -123,13 +143,97 @@ func (v *Issuer) Issue(ctx context.Context, commonName string, conf *certify.Cer
 	return &tlsCert, nil
 }
 
-func (v Issuer) signCSR(ctx context.Context, opts csrOpts) (*api.Secret, error) {
-	pkiMountName := "pki"
+// CAChain fetches the CA certificate chain for the issuer identified by
+// IssuerRef (or the mount's default issuer, if unset), so that callers
+// can pre-populate trust bundles or perform key rollovers without
+// changing the PKI mount in use.
+func (v *Issuer) CAChain(ctx context.Context) ([]byte, error) {
+	if v.cli == nil { // Could be set by FromClient
+		if err := v.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	issuerRef := v.IssuerRef
+	if issuerRef == "" {
+		issuerRef = "default"
+	}
+
+	r := v.cli.NewRequest("GET", "/v1/"+v.mount()+"/issuer/"+issuerRef+"/json")
+	resp, err := v.cli.RawRequestWithContext(ctx, r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := api.ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// https://developer.hashicorp.com/vault/api-docs/secret/pki#read-issuer
+	chainPEM := []byte(secret.Data["certificate"].(string))
+	if caChain, ok := secret.Data["ca_chain"]; ok {
+		for _, pemData := range caChain.([]interface{}) {
+			chainPEM = append(append(chainPEM, '\n'), []byte(pemData.(string))...)
+		}
+	}
+
+	return chainPEM, nil
+}
+
+// Revoke implements certify.Revoker, revoking cert with the configured
+// Vault PKI mount by its serial number. Vault has no concept of a
+// revocation reason, so reason is accepted for interface compatibility
+// but otherwise ignored.
+func (v *Issuer) Revoke(ctx context.Context, cert *x509.Certificate, reason certify.RevocationReason) error {
+	if v.cli == nil { // Could be set by FromClient
+		if err := v.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	r := v.cli.NewRequest("PUT", "/v1/"+v.mount()+"/revoke")
+	if err := r.SetJSONBody(map[string]string{
+		"serial_number": serialString(cert.SerialNumber),
+	}); err != nil {
+		return err
+	}
+
+	resp, err := v.cli.RawRequestWithContext(ctx, r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	return err
+}
+
+// serialString formats n in the colon-separated hex form Vault's PKI
+// secrets engine expects for a certificate serial number.
+func serialString(n *big.Int) string {
+	b := n.Bytes()
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = hex.EncodeToString([]byte{c})
+	}
+	return strings.Join(parts, ":")
+}
+
+func (v Issuer) mount() string {
 	if v.Mount != "" {
-		pkiMountName = v.Mount
+		return v.Mount
+	}
+	return "pki"
+}
+
+func (v Issuer) signCSR(ctx context.Context, opts csrOpts) (*api.Secret, error) {
+	path := "/v1/" + v.mount() + "/sign/" + v.Role
+	if v.IssuerRef != "" {
+		path = "/v1/" + v.mount() + "/issuer/" + v.IssuerRef + "/sign/" + v.Role
 	}
 
-	r := v.cli.NewRequest("PUT", "/v1/"+pkiMountName+"/sign/"+v.Role)
+	r := v.cli.NewRequest("PUT", path)
 	if err := r.SetJSONBody(opts); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+)
+
+func newTestIssuer(t *testing.T, handler http.HandlerFunc) (*Issuer, func()) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+
+	vConf := api.DefaultConfig()
+	vConf.Address = srv.URL
+	cli, err := api.NewClient(vConf)
+	if err != nil {
+		t.Fatalf("building vault client: %v", err)
+	}
+
+	u, _ := url.Parse(srv.URL)
+	return &Issuer{
+		URL:  u,
+		Role: "my-role",
+		cli:  cli,
+	}, srv.Close
+}
+
+func TestSignCSRRouting(t *testing.T) {
+	tests := []struct {
+		name      string
+		mount     string
+		issuerRef string
+		wantPath  string
+	}{
+		{
+			name:     "default mount, no issuer ref",
+			wantPath: "/v1/pki/sign/my-role",
+		},
+		{
+			name:     "custom mount, no issuer ref",
+			mount:    "pki-int",
+			wantPath: "/v1/pki-int/sign/my-role",
+		},
+		{
+			name:      "default mount, with issuer ref",
+			issuerRef: "my-issuer",
+			wantPath:  "/v1/pki/issuer/my-issuer/sign/my-role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			iss, closeSrv := newTestIssuer(t, func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"data": map[string]interface{}{
+						"certificate": "",
+					},
+				})
+			})
+			defer closeSrv()
+
+			iss.Mount = tt.mount
+			iss.IssuerRef = tt.issuerRef
+
+			_, _ = iss.signCSR(context.Background(), csrOpts{})
+
+			if gotPath != tt.wantPath {
+				t.Errorf("signCSR routed to %q, want %q", gotPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestCAChain(t *testing.T) {
+	var gotPath string
+	iss, closeSrv := newTestIssuer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"certificate": "leaf-pem",
+				"ca_chain":    []interface{}{"intermediate-pem", "root-pem"},
+			},
+		})
+	})
+	defer closeSrv()
+	iss.IssuerRef = "my-issuer"
+
+	chain, err := iss.CAChain(context.Background())
+	if err != nil {
+		t.Fatalf("CAChain() returned error: %v", err)
+	}
+
+	wantPath := "/v1/pki/issuer/my-issuer/json"
+	if gotPath != wantPath {
+		t.Errorf("CAChain routed to %q, want %q", gotPath, wantPath)
+	}
+
+	want := "leaf-pem\nintermediate-pem\nroot-pem"
+	if string(chain) != want {
+		t.Errorf("CAChain() = %q, want %q", chain, want)
+	}
+}
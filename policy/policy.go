@@ -0,0 +1,144 @@
+// Package policy provides certify.SignPolicy implementations for
+// constraining the certificate signing requests issued through a
+// certify.Certify or Issuer, so that a single issuer can be safely shared
+// across many workloads.
+package policy
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/utilitywarehouse/certify"
+)
+
+// AllowedDomains rejects a CSR whose DNS SANs or Common Name aren't a
+// subdomain of (or equal to) one of the configured suffixes, mirroring
+// the permitted DNS domains constraint used by upstream CAs.
+type AllowedDomains struct {
+	// Suffixes is the list of DNS suffixes SANs are permitted to fall
+	// under, e.g. "example.com" allows "foo.example.com" and
+	// "example.com" itself, but not "evilexample.com".
+	Suffixes []string
+	// AllowIPSANs permits IP address SANs when true. Defaults to false.
+	AllowIPSANs bool
+}
+
+var _ certify.SignPolicy = AllowedDomains{}
+
+// Approve implements certify.SignPolicy.
+func (p AllowedDomains) Approve(csr *x509.CertificateRequest) error {
+	if !p.AllowIPSANs && len(csr.IPAddresses) > 0 {
+		return fmt.Errorf("policy: IP SANs are not permitted")
+	}
+
+	names := append([]string{}, csr.DNSNames...)
+	if csr.Subject.CommonName != "" {
+		names = append(names, csr.Subject.CommonName)
+	}
+
+	for _, name := range names {
+		if !p.allowed(name) {
+			return fmt.Errorf("policy: %q is not within an allowed domain", name)
+		}
+	}
+	return nil
+}
+
+func (p AllowedDomains) allowed(name string) bool {
+	for _, suffix := range p.Suffixes {
+		if name == suffix || strings.HasSuffix(name, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxTTL rejects a CSR that doesn't request, via the certify.OIDRequestedTTL
+// extension (populated from CertConfig.RequestedTTL by
+// internal/csr.FromCertConfig), a certificate lifetime no greater than Max.
+// Like RequireSPIFFETrustDomain, it denies by default: a
+// CSR that omits the extension entirely (CertConfig.RequestedTTL left at its
+// zero value) is rejected rather than let through, since otherwise a caller
+// could bypass the cap simply by not declaring a TTL. Callers must set
+// CertConfig.RequestedTTL on every request that goes through a Certify or
+// Issuer configured with MaxTTL.
+type MaxTTL struct {
+	Max time.Duration
+}
+
+var _ certify.SignPolicy = MaxTTL{}
+
+// Approve implements certify.SignPolicy.
+func (p MaxTTL) Approve(csr *x509.CertificateRequest) error {
+	var (
+		found   bool
+		seconds int64
+	)
+	for _, ext := range csr.ExtraExtensions {
+		if !ext.Id.Equal(certify.OIDRequestedTTL) {
+			continue
+		}
+
+		if _, err := asn1.Unmarshal(ext.Value, &seconds); err != nil {
+			return fmt.Errorf("policy: parsing requested TTL extension: %w", err)
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("policy: CSR does not declare a requested TTL via CertConfig.RequestedTTL, so MaxTTL cannot enforce a cap on it")
+	}
+
+	if requested := time.Duration(seconds) * time.Second; requested > p.Max {
+		return fmt.Errorf("policy: requested TTL %s exceeds maximum of %s", requested, p.Max)
+	}
+	return nil
+}
+
+// RequireSPIFFETrustDomain rejects a CSR that doesn't carry exactly one
+// SPIFFE ID (a "spiffe://" URI SAN) under one of the configured trust
+// domains. It's intended to pair with certify.CertConfig.SpiffeID when
+// minting workload identities.
+type RequireSPIFFETrustDomain struct {
+	// TrustDomains is the list of SPIFFE trust domains (the host part of
+	// a "spiffe://<trust-domain>/..." URI) permitted in the CSR.
+	TrustDomains []string
+}
+
+var _ certify.SignPolicy = RequireSPIFFETrustDomain{}
+
+// Approve implements certify.SignPolicy.
+func (p RequireSPIFFETrustDomain) Approve(csr *x509.CertificateRequest) error {
+	var spiffeIDs []*url.URL
+	for _, u := range csr.URIs {
+		if u.Scheme != "spiffe" {
+			continue
+		}
+
+		if !p.trustDomainAllowed(u.Host) {
+			return fmt.Errorf("policy: spiffe id %q is not within an allowed trust domain", u)
+		}
+		spiffeIDs = append(spiffeIDs, u)
+	}
+
+	switch {
+	case len(spiffeIDs) == 0:
+		return fmt.Errorf("policy: CSR has no spiffe:// URI SAN within an allowed trust domain")
+	case len(spiffeIDs) > 1:
+		return fmt.Errorf("policy: CSR must carry exactly one spiffe:// URI SAN, found %d", len(spiffeIDs))
+	}
+	return nil
+}
+
+func (p RequireSPIFFETrustDomain) trustDomainAllowed(host string) bool {
+	for _, domain := range p.TrustDomains {
+		if host == domain {
+			return true
+		}
+	}
+	return false
+}
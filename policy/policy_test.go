@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/utilitywarehouse/certify"
+)
+
+func TestAllowedDomains(t *testing.T) {
+	p := AllowedDomains{Suffixes: []string{"example.com"}}
+
+	if err := p.Approve(&x509.CertificateRequest{DNSNames: []string{"foo.example.com"}}); err != nil {
+		t.Errorf("expected allowed subdomain to be approved, got %v", err)
+	}
+	if err := p.Approve(&x509.CertificateRequest{DNSNames: []string{"evilexample.com"}}); err == nil {
+		t.Error("expected non-matching domain to be rejected")
+	}
+	if err := p.Approve(&x509.CertificateRequest{IPAddresses: []net.IP{net.IPv4(1, 2, 3, 4)}}); err == nil {
+		t.Error("expected IP SAN to be rejected by default")
+	}
+}
+
+func TestAllowedDomainsDoesNotMutateSharedDNSNames(t *testing.T) {
+	p := AllowedDomains{Suffixes: []string{"example.com"}}
+
+	// base has spare capacity, as would a slice built via append()
+	// elsewhere and shared between CSRs (e.g. a common base SAN list).
+	base := make([]string, 1, 2)
+	base[0] = "foo.example.com"
+	shared := base[:1]
+
+	if err := p.Approve(&x509.CertificateRequest{
+		DNSNames: shared,
+		Subject:  pkix.Name{CommonName: "bar.example.com"},
+	}); err != nil {
+		t.Fatalf("expected allowed domain to be approved, got %v", err)
+	}
+
+	if got, want := base[:2][1], ""; got != want {
+		t.Errorf("Approve mutated the caller's backing array past its length: base[1] = %q, want %q", got, want)
+	}
+}
+
+func TestMaxTTL(t *testing.T) {
+	p := MaxTTL{Max: time.Hour}
+
+	requestedTTL := func(d time.Duration) x509.CertificateRequest {
+		val, _ := asn1.Marshal(int64(d / time.Second))
+		return x509.CertificateRequest{
+			ExtraExtensions: []pkix.Extension{{Id: certify.OIDRequestedTTL, Value: val}},
+		}
+	}
+
+	within := requestedTTL(30 * time.Minute)
+	if err := p.Approve(&within); err != nil {
+		t.Errorf("expected TTL within max to be approved, got %v", err)
+	}
+
+	tooLong := requestedTTL(2 * time.Hour)
+	if err := p.Approve(&tooLong); err == nil {
+		t.Error("expected TTL exceeding max to be rejected")
+	}
+
+	if err := p.Approve(&x509.CertificateRequest{}); err == nil {
+		t.Error("expected CSR with no requested TTL to be rejected, since MaxTTL denies by default")
+	}
+}
+
+func TestRequireSPIFFETrustDomain(t *testing.T) {
+	p := RequireSPIFFETrustDomain{TrustDomains: []string{"example.org"}}
+
+	allowed, _ := url.Parse("spiffe://example.org/ns/default/sa/my-workload")
+	if err := p.Approve(&x509.CertificateRequest{URIs: []*url.URL{allowed}}); err != nil {
+		t.Errorf("expected spiffe id in allowed trust domain to be approved, got %v", err)
+	}
+
+	disallowed, _ := url.Parse("spiffe://evil.org/ns/default/sa/my-workload")
+	if err := p.Approve(&x509.CertificateRequest{URIs: []*url.URL{disallowed}}); err == nil {
+		t.Error("expected spiffe id outside allowed trust domain to be rejected")
+	}
+
+	if err := p.Approve(&x509.CertificateRequest{}); err == nil {
+		t.Error("expected CSR with no spiffe id to be rejected")
+	}
+
+	if err := p.Approve(&x509.CertificateRequest{URIs: []*url.URL{allowed, disallowed}}); err == nil {
+		t.Error("expected CSR carrying a spiffe id outside an allowed trust domain to be rejected, even alongside an allowed one")
+	}
+}